@@ -0,0 +1,12 @@
+// Command qmlint runs the qmlint analyzer as a stand-alone vet-style tool.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/HJLebbink/quine-mccluskey-petrick-agent/qmlint"
+)
+
+func main() {
+	singlechecker.Main(qmlint.Analyzer)
+}
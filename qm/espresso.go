@@ -0,0 +1,269 @@
+package qm
+
+// Function is a boolean function described by its on-set and (optionally) a
+// don't-care set, both as minterms over Vars input variables.
+type Function struct {
+	Vars int
+	On   []Minterm
+	DC   []Minterm
+}
+
+// MinimizeHeuristic implements the inner loop of Espresso-II: EXPAND each
+// cube against the off-set, discard redundant cubes with an irredundant
+// (unate) cover, REDUCE each surviving cube to the smallest cube justified
+// by its own essential minterms, and repeat until a full pass produces no
+// further cost reduction.
+//
+// Unlike Minimize, this does not guarantee a minimum cover, but it scales
+// to the 20-30 variable functions that make exact Quine–McCluskey +
+// Petrick impractical.
+func MinimizeHeuristic(f Function) Cover {
+	if len(f.On) == 0 {
+		return nil
+	}
+	off := complementCubes(f.Vars, append(append([]Minterm{}, f.On...), f.DC...))
+
+	cover := initialCover(f.Vars, f.On)
+	cover = expandAll(cover, off, f.Vars)
+	cover = irredundant(f.Vars, cover, f.On)
+
+	for {
+		cost := coverCost(cover)
+		next := reduceAll(f.Vars, cover, f.On)
+		next = expandAll(next, off, f.Vars)
+		next = irredundant(f.Vars, next, f.On)
+		if !less(coverCost(next), cost) {
+			break
+		}
+		cover = next
+	}
+	return cover
+}
+
+func initialCover(vars int, on []Minterm) Cover {
+	seen := map[string]bool{}
+	var cover Cover
+	for _, m := range on {
+		c := bitsOf(m, vars)
+		k := cubeKey(c)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		cover = append(cover, c)
+	}
+	return cover
+}
+
+// complementCubes computes the off-set (every minterm not in occupied) as a
+// set of pairwise disjoint cubes (not necessarily the fewest possible), via
+// recursive Shannon expansion: at each step split
+// on the most-binate remaining variable (the one whose 0/1 split of the
+// occupied minterms is most even), so the recursion depth and intermediate
+// cube count stay small instead of enumerating every minterm.
+func complementCubes(vars int, occupied []Minterm) Cover {
+	free := make([]int, vars)
+	for i := range free {
+		free[i] = i
+	}
+	fixed := make(Cube, vars)
+	for i := range fixed {
+		fixed[i] = DontCare
+	}
+	return complementRec(vars, free, occupied, fixed)
+}
+
+func complementRec(vars int, free []int, occupied []Minterm, fixed Cube) Cover {
+	if len(occupied) == 0 {
+		return Cover{append(Cube(nil), fixed...)}
+	}
+	if len(free) == 0 {
+		return nil // this single point is occupied; no complement here
+	}
+
+	splitAt, splitIdx := mostBinate(vars, free, occupied)
+	remaining := make([]int, 0, len(free)-1)
+	for i, v := range free {
+		if i != splitIdx {
+			remaining = append(remaining, v)
+		}
+	}
+
+	var zero, one []Minterm
+	for _, m := range occupied {
+		if bitAt(m, vars, splitAt) == One {
+			one = append(one, m)
+		} else {
+			zero = append(zero, m)
+		}
+	}
+
+	fixedZero := append(Cube(nil), fixed...)
+	fixedZero[splitAt] = Zero
+	fixedOne := append(Cube(nil), fixed...)
+	fixedOne[splitAt] = One
+
+	var out Cover
+	out = append(out, complementRec(vars, remaining, zero, fixedZero)...)
+	out = append(out, complementRec(vars, remaining, one, fixedOne)...)
+	return out
+}
+
+// mostBinate returns the variable (and its index within free) whose 0/1
+// split of occupied is most even, i.e. the variable that currently
+// constrains the subspace the least.
+func mostBinate(vars int, free []int, occupied []Minterm) (v int, idx int) {
+	bestBalance := -1
+	for i, candidate := range free {
+		c0, c1 := 0, 0
+		for _, m := range occupied {
+			if bitAt(m, vars, candidate) == One {
+				c1++
+			} else {
+				c0++
+			}
+		}
+		balance := c0
+		if c1 < balance {
+			balance = c1
+		}
+		if balance > bestBalance {
+			bestBalance = balance
+			v, idx = candidate, i
+		}
+	}
+	return v, idx
+}
+
+// expandAll grows every cube in cover as far as it can go while staying
+// disjoint from off, then removes any duplicates that introduces.
+func expandAll(cover Cover, off Cover, vars int) Cover {
+	seen := map[string]bool{}
+	var out Cover
+	for _, c := range cover {
+		expanded := expandCube(c, off, vars)
+		k := cubeKey(expanded)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, expanded)
+	}
+	return out
+}
+
+// expandCube greedily drops literals (turning them into don't-cares) one at
+// a time, keeping each drop only if the wider cube still doesn't intersect
+// any off-set cube.
+func expandCube(c Cube, off Cover, vars int) Cube {
+	out := append(Cube(nil), c...)
+	for i := 0; i < vars; i++ {
+		if out[i] == DontCare {
+			continue
+		}
+		saved := out[i]
+		out[i] = DontCare
+		if intersectsAny(out, off) {
+			out[i] = saved
+		}
+	}
+	return out
+}
+
+func intersectsAny(c Cube, off Cover) bool {
+	for _, o := range off {
+		if cubesIntersect(c, o) {
+			return true
+		}
+	}
+	return false
+}
+
+func cubesIntersect(a, b Cube) bool {
+	for i := range a {
+		if a[i] != DontCare && b[i] != DontCare && a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// irredundant removes cubes from cover that aren't needed to cover on; it's
+// the same unate-covering search Minimize uses for Petrick's method (a full
+// product-of-sums expansion with absorption), just run against an
+// already-expanded cover instead of
+// the full set of prime implicants.
+func irredundant(vars int, cover Cover, on []Minterm) Cover {
+	return petrick(vars, cover, on)
+}
+
+// reduceAll shrinks each cube in cover to the smallest cube still
+// justified by the on-minterms it alone covers, so a subsequent EXPAND can
+// land on a different (hopefully smaller) local optimum.
+func reduceAll(vars int, cover Cover, on []Minterm) Cover {
+	out := make(Cover, len(cover))
+	for i, c := range cover {
+		out[i] = reduceCube(vars, c, cover, i, on)
+	}
+	return out
+}
+
+func reduceCube(vars int, c Cube, cover Cover, self int, on []Minterm) Cube {
+	var essential []Minterm
+	for _, m := range on {
+		if !c.Covers(m, vars) {
+			continue
+		}
+		coveredElsewhere := false
+		for j, other := range cover {
+			if j == self {
+				continue
+			}
+			if other.Covers(m, vars) {
+				coveredElsewhere = true
+				break
+			}
+		}
+		if !coveredElsewhere {
+			essential = append(essential, m)
+		}
+	}
+	if len(essential) == 0 {
+		return c
+	}
+	reduced := append(Cube(nil), bitsOf(essential[0], vars)...)
+	for _, m := range essential[1:] {
+		bits := bitsOf(m, vars)
+		for i := range reduced {
+			if reduced[i] != bits[i] {
+				reduced[i] = DontCare
+			}
+		}
+	}
+	return reduced
+}
+
+type cost struct {
+	cubes, literals int
+}
+
+func coverCost(cover Cover) cost {
+	c := cost{cubes: len(cover)}
+	for _, cube := range cover {
+		for _, t := range cube {
+			if t != DontCare {
+				c.literals++
+			}
+		}
+	}
+	return c
+}
+
+// less reports whether a is a strict improvement over b: fewer cubes, or
+// the same number of cubes with fewer literals.
+func less(a, b cost) bool {
+	if a.cubes != b.cubes {
+		return a.cubes < b.cubes
+	}
+	return a.literals < b.literals
+}
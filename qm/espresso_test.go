@@ -0,0 +1,52 @@
+package qm
+
+import "testing"
+
+// TestMinimizeHeuristicMatchesOnSet mirrors canAccessFeature from
+// examples/agent/go/feature_access.go: isAdmin || (isPremium && isBeta).
+func TestMinimizeHeuristicMatchesOnSet(t *testing.T) {
+	const vars = 3 // isPremium, isBeta, isAdmin
+	var on []Minterm
+	for m := 0; m < 1<<vars; m++ {
+		isPremium := m>>2&1 == 1
+		isBeta := m>>1&1 == 1
+		isAdmin := m&1 == 1
+		if isAdmin || (isPremium && isBeta) {
+			on = append(on, Minterm(m))
+		}
+	}
+
+	cover := MinimizeHeuristic(Function{Vars: vars, On: on})
+
+	onSet := map[Minterm]bool{}
+	for _, m := range on {
+		onSet[m] = true
+	}
+	for m := 0; m < 1<<vars; m++ {
+		want := onSet[Minterm(m)]
+		got := evalCover(cover, Minterm(m), vars)
+		if got != want {
+			t.Errorf("minterm %03b: cover says %v, want %v", m, got, want)
+		}
+	}
+}
+
+func TestComplementCubesIsDisjointFromOccupied(t *testing.T) {
+	const vars = 4
+	occupied := []Minterm{0, 1, 5, 6, 15}
+	off := complementCubes(vars, occupied)
+
+	occSet := map[Minterm]bool{}
+	for _, m := range occupied {
+		occSet[m] = true
+	}
+	for m := 0; m < 1<<vars; m++ {
+		inOff := evalCover(off, Minterm(m), vars)
+		if occSet[Minterm(m)] && inOff {
+			t.Errorf("minterm %d is occupied but also covered by the complement", m)
+		}
+		if !occSet[Minterm(m)] && !inOff {
+			t.Errorf("minterm %d is unoccupied but missing from the complement", m)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package qm
+
+// PredicateTags describes what's known about an atomic boolean predicate
+// lifted from real Go source, inferred from its AST shape: a bare
+// identifier or field select is Pure, while anything built from a call,
+// channel receive, or index expression is at least Costly or MayPanic.
+// A predicate can carry more than one tag at once — db.Lookup(id) is both
+// Costly and SideEffecting, for instance — so these are independent flags
+// rather than a single enum.
+type PredicateTags struct {
+	Costly        bool // worth gating behind cheaper checks
+	SideEffecting bool // must not be reordered relative to other SideEffecting predicates
+	MayPanic      bool // must not be evaluated somewhere short-circuiting would have skipped it
+}
+
+// Pure reports whether t carries none of the above tags.
+func (t PredicateTags) Pure() bool {
+	return !t.Costly && !t.SideEffecting && !t.MayPanic
+}
+
+// Predicate is one atomic boolean condition together with its inferred
+// tags. Name is whatever the importer used to key it (typically the
+// predicate's printed source text).
+type Predicate struct {
+	Name string
+	Tags PredicateTags
+}
+
+// PredicateModel sits between an AST importer and the minimizer: it
+// records what's known about each of a Cube's literal positions, and
+// answers the two questions the minimizer needs to stay correct when
+// predicates aren't free of side effects:
+//
+//   - OrderLiterals: what left-to-right evaluation order keeps
+//     SideEffecting predicates in their original relative order and gates
+//     Costly predicates behind cheap ones, without moving anything across
+//     a SideEffecting or MayPanic predicate.
+//   - Skip: whether a candidate cube would evaluate a MayPanic predicate
+//     on a path the original source would have short-circuited before
+//     reaching it.
+type PredicateModel struct {
+	// Predicates is indexed the same way as a Cube's literal positions.
+	Predicates []Predicate
+}
+
+// NewPredicateModel builds a PredicateModel from predicates already
+// ordered to match Cube literal positions.
+func NewPredicateModel(predicates []Predicate) PredicateModel {
+	return PredicateModel{Predicates: predicates}
+}
+
+// OrderLiterals reorders indices (cube literal positions, supplied in
+// original source order) into a left-to-right evaluation order safe to
+// render as a single `&&`/`||` chain:
+//
+//   - two SideEffecting (or MayPanic) predicates never swap relative order
+//   - a Pure or Costly predicate never crosses a SideEffecting or MayPanic
+//     one, since that could change what runs before it
+//   - between two such anchors, Pure predicates are placed ahead of Costly
+//     ones, so a cheap check still gates an expensive one where possible
+func (m PredicateModel) OrderLiterals(indices []int) []int {
+	if len(indices) == 0 {
+		return indices
+	}
+	out := make([]int, 0, len(indices))
+	var float []int
+	flush := func() {
+		var pure, costly []int
+		for _, idx := range float {
+			if m.Predicates[idx].Tags.Costly {
+				costly = append(costly, idx)
+			} else {
+				pure = append(pure, idx)
+			}
+		}
+		out = append(out, pure...)
+		out = append(out, costly...)
+		float = nil
+	}
+	for _, idx := range indices {
+		tags := m.Predicates[idx].Tags
+		if tags.SideEffecting || tags.MayPanic {
+			flush()
+			out = append(out, idx)
+			continue
+		}
+		float = append(float, idx)
+	}
+	flush()
+	return out
+}
+
+// Guard pairs a prerequisite literal position with the value it had to
+// hold, in the original guard expression, for evaluation to ever reach the
+// position it guards. Any means the exact polarity couldn't be pinned down
+// (the prerequisite sat under a shape more complex than a NOT/&&/|| chain
+// the importer can reason about precisely) and only "was fixed to some
+// value at all" can be required; otherwise Require is the exact value
+// (One if the prerequisite had to hold true, Zero if it had to hold false).
+type Guard struct {
+	Pos     int
+	Require Trit
+	Any     bool
+}
+
+// Skip reports whether cube is unsafe to render: it fixes (requires true
+// or false) a MayPanic predicate without also fixing every predicate that
+// guarded it in the original source to the polarity that actually reaches
+// it. guardedBy[i] lists the prerequisites position i's original guard
+// expression had to satisfy before position i was ever evaluated; the AST
+// importer derives it by walking each guard's &&/|| chain. A prerequisite
+// left DontCare, or fixed to the wrong polarity, means the cube would
+// evaluate position i on a path the original would have short-circuited
+// past it.
+func (m PredicateModel) Skip(cube Cube, guardedBy map[int][]Guard) bool {
+	for i, t := range cube {
+		if t == DontCare || !m.Predicates[i].Tags.MayPanic {
+			continue
+		}
+		for _, g := range guardedBy[i] {
+			if g.Any {
+				if cube[g.Pos] == DontCare {
+					return true
+				}
+				continue
+			}
+			if cube[g.Pos] != g.Require {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package qm
+
+import "testing"
+
+func TestOrderLiteralsGatesCostlyBehindPure(t *testing.T) {
+	m := NewPredicateModel([]Predicate{
+		{Name: "isCostly", Tags: PredicateTags{Costly: true}},
+		{Name: "isPure", Tags: PredicateTags{}},
+	})
+	got := m.OrderLiterals([]int{0, 1})
+	if len(got) != 2 || got[0] != 1 || got[1] != 0 {
+		t.Errorf("expected the pure predicate first, got %v", got)
+	}
+}
+
+func TestOrderLiteralsNeverCrossesAnAnchor(t *testing.T) {
+	m := NewPredicateModel([]Predicate{
+		{Name: "a", Tags: PredicateTags{}},
+		{Name: "sideEffecting", Tags: PredicateTags{SideEffecting: true}},
+		{Name: "b", Tags: PredicateTags{}},
+	})
+	got := m.OrderLiterals([]int{0, 1, 2})
+	want := []int{0, 1, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("OrderLiterals(0,1,2) = %v, want %v (anchor at 1 must not move)", got, want)
+		}
+	}
+}
+
+func TestSkipRejectsUnguardedMayPanic(t *testing.T) {
+	// Models `ok && lookup()`: lookup() is only ever reached once ok is
+	// known true, so its guard requires position 0 to be One.
+	m := NewPredicateModel([]Predicate{
+		{Name: "ok", Tags: PredicateTags{}},
+		{Name: "lookup()", Tags: PredicateTags{MayPanic: true}},
+	})
+	guardedBy := map[int][]Guard{1: {{Pos: 0, Require: One}}}
+
+	unsafe := Cube{DontCare, One} // requires lookup() without requiring ok
+	if !m.Skip(unsafe, guardedBy) {
+		t.Errorf("expected Skip to reject a cube that drops the guard for a MayPanic predicate")
+	}
+
+	wrongPolarity := Cube{Zero, One} // requires lookup() with ok forced false
+	if !m.Skip(wrongPolarity, guardedBy) {
+		t.Errorf("expected Skip to reject a cube that fixes the guard to the polarity that never reaches lookup()")
+	}
+
+	safe := Cube{One, One}
+	if m.Skip(safe, guardedBy) {
+		t.Errorf("expected Skip to accept a cube that keeps the guard fixed to the reaching polarity")
+	}
+}
+
+func TestSkipAnyGuardRejectsOnlyDontCare(t *testing.T) {
+	// An Any guard stands in when the importer couldn't pin an exact
+	// polarity (e.g. the prerequisite sat under a shape more complex than
+	// a plain NOT/&&/|| chain); it should still demand the prerequisite be
+	// fixed to *some* value, without caring which.
+	m := NewPredicateModel([]Predicate{
+		{Name: "ok", Tags: PredicateTags{}},
+		{Name: "lookup()", Tags: PredicateTags{MayPanic: true}},
+	})
+	guardedBy := map[int][]Guard{1: {{Pos: 0, Any: true}}}
+
+	if !m.Skip(Cube{DontCare, One}, guardedBy) {
+		t.Errorf("expected Skip to reject an Any guard left DontCare")
+	}
+	if m.Skip(Cube{Zero, One}, guardedBy) {
+		t.Errorf("expected Skip to accept an Any guard fixed to either polarity")
+	}
+	if m.Skip(Cube{One, One}, guardedBy) {
+		t.Errorf("expected Skip to accept an Any guard fixed to either polarity")
+	}
+}
@@ -0,0 +1,299 @@
+// Package qm implements Quine–McCluskey minimization of boolean functions,
+// finishing with Petrick's method to pick a minimal sum-of-products cover.
+//
+// It underlies the qmlint analyzer: callers lift a truth table out of Go
+// source (see qmlint) and hand it to Minimize to get back the smallest
+// equivalent set of product terms.
+package qm
+
+// Minterm is a single row of a boolean truth table, encoded as the bits of
+// its input variables. Variable 0 is the most significant bit.
+type Minterm int
+
+// Trit is a single ternary literal within a Cube.
+type Trit int8
+
+const (
+	Zero     Trit = iota // literal fixed to 0
+	One                  // literal fixed to 1
+	DontCare             // "-", matches either value
+)
+
+func (t Trit) String() string {
+	switch t {
+	case Zero:
+		return "0"
+	case One:
+		return "1"
+	default:
+		return "-"
+	}
+}
+
+// Cube is a product term: one Trit per input variable.
+type Cube []Trit
+
+// Covers reports whether m satisfies every fixed literal in c.
+func (c Cube) Covers(m Minterm, vars int) bool {
+	for i := 0; i < vars; i++ {
+		if c[i] == DontCare {
+			continue
+		}
+		if c[i] != bitAt(m, vars, i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Cover is a sum of product terms.
+type Cover []Cube
+
+func bitAt(m Minterm, vars, i int) Trit {
+	if (int(m)>>uint(vars-1-i))&1 == 1 {
+		return One
+	}
+	return Zero
+}
+
+func bitsOf(m Minterm, vars int) Cube {
+	c := make(Cube, vars)
+	for i := 0; i < vars; i++ {
+		c[i] = bitAt(m, vars, i)
+	}
+	return c
+}
+
+func popcount(c Cube) int {
+	n := 0
+	for _, t := range c {
+		if t == One {
+			n++
+		}
+	}
+	return n
+}
+
+func cubeKey(c Cube) string {
+	b := make([]byte, len(c))
+	for i, t := range c {
+		b[i] = byte('0' + t)
+	}
+	return string(b)
+}
+
+// combine merges a and b into a single cube one literal wider if they agree
+// on every don't-care and differ in exactly one fixed literal.
+func combine(a, b Cube) (Cube, bool) {
+	diff := -1
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+		if a[i] == DontCare || b[i] == DontCare {
+			return nil, false
+		}
+		if diff != -1 {
+			return nil, false
+		}
+		diff = i
+	}
+	if diff == -1 {
+		return nil, false
+	}
+	out := append(Cube(nil), a...)
+	out[diff] = DontCare
+	return out, true
+}
+
+// Minimize runs exact Quine–McCluskey over the given number of variables,
+// followed by Petrick's method, and returns a minimal sum-of-products cover
+// of the on-set. vars is the number of input variables, on lists the
+// minterms the function must evaluate to true for, and dc lists minterms
+// the cover is free to claim (or not) without being judged for it — for
+// example because an earlier guard already handles them and the rest of
+// the function never sees them. Pass a nil dc for a plain on-set.
+func Minimize(vars int, on, dc []Minterm) Cover {
+	if len(on) == 0 {
+		return nil
+	}
+	primes := primeImplicants(vars, on, dc)
+	return petrick(vars, primes, on)
+}
+
+// primeImplicants combines minterms (on ∪ dc) round by round, grouping by
+// population count, until no further combination is possible. Cubes that
+// were never combined in a round are prime implicants.
+func primeImplicants(vars int, on, dc []Minterm) Cover {
+	seen := make(map[string]bool)
+	maxOnes := vars + 1
+	buckets := make([][]Cube, maxOnes+1)
+	addTerm := func(m Minterm) {
+		c := bitsOf(m, vars)
+		k := cubeKey(c)
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		buckets[popcount(c)] = append(buckets[popcount(c)], c)
+	}
+	for _, m := range on {
+		addTerm(m)
+	}
+	for _, m := range dc {
+		addTerm(m)
+	}
+
+	current := buckets
+	var primes Cover
+	seenCombined := make(map[string]bool)
+	for {
+		used := make(map[string]bool)
+		nextSeen := make(map[string]bool)
+		next := make([][]Cube, maxOnes+1)
+		any := false
+		for ones := 0; ones < maxOnes; ones++ {
+			for _, a := range current[ones] {
+				for _, b := range current[ones+1] {
+					merged, ok := combine(a, b)
+					if !ok {
+						continue
+					}
+					any = true
+					used[cubeKey(a)] = true
+					used[cubeKey(b)] = true
+					k := cubeKey(merged)
+					if nextSeen[k] {
+						continue
+					}
+					nextSeen[k] = true
+					next[popcount(merged)] = append(next[popcount(merged)], merged)
+				}
+			}
+		}
+		for ones := range current {
+			for _, c := range current[ones] {
+				if !used[cubeKey(c)] {
+					k := cubeKey(c)
+					if !seenCombined[k] {
+						seenCombined[k] = true
+						primes = append(primes, c)
+					}
+				}
+			}
+		}
+		if !any {
+			break
+		}
+		current = next
+	}
+	return primes
+}
+
+// petrick selects a minimal-size subset of primes whose union covers every
+// on-minterm.
+func petrick(vars int, primes Cover, on []Minterm) Cover {
+	if len(primes) == 0 {
+		return nil
+	}
+	// sop represents a product-of-sums-turned-sum-of-products as
+	// a set of clauses, each clause a set of prime indices whose product
+	// satisfies the covering requirement so far.
+	var sop []map[int]bool
+	for _, m := range on {
+		var coverers []int
+		for i, p := range primes {
+			if p.Covers(m, vars) {
+				coverers = append(coverers, i)
+			}
+		}
+		clause := make([]map[int]bool, len(coverers))
+		for i, idx := range coverers {
+			clause[i] = map[int]bool{idx: true}
+		}
+		if sop == nil {
+			sop = clause
+		} else {
+			sop = multiply(sop, clause)
+		}
+	}
+	best := smallestTerm(sop)
+	cover := make(Cover, 0, len(best))
+	indices := make([]int, 0, len(best))
+	for idx := range best {
+		indices = append(indices, idx)
+	}
+	sortInts(indices)
+	for _, idx := range indices {
+		cover = append(cover, primes[idx])
+	}
+	return cover
+}
+
+// multiply distributes two sums of products and removes any term that is a
+// superset of another (absorption), keeping the representation small.
+func multiply(a, b []map[int]bool) []map[int]bool {
+	var out []map[int]bool
+	for _, ta := range a {
+		for _, tb := range b {
+			merged := make(map[int]bool, len(ta)+len(tb))
+			for k := range ta {
+				merged[k] = true
+			}
+			for k := range tb {
+				merged[k] = true
+			}
+			out = append(out, merged)
+		}
+	}
+	return absorb(out)
+}
+
+// absorb drops any term that is a strict superset of another term in the
+// same set, since the smaller term already implies it.
+func absorb(terms []map[int]bool) []map[int]bool {
+	var out []map[int]bool
+	for i, t := range terms {
+		subsumed := false
+		for j, u := range terms {
+			if i == j || len(u) >= len(t) {
+				continue
+			}
+			if isSubset(u, t) {
+				subsumed = true
+				break
+			}
+		}
+		if !subsumed {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func isSubset(small, big map[int]bool) bool {
+	for k := range small {
+		if !big[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func smallestTerm(sop []map[int]bool) map[int]bool {
+	var best map[int]bool
+	for _, t := range sop {
+		if best == nil || len(t) < len(best) {
+			best = t
+		}
+	}
+	return best
+}
+
+func sortInts(s []int) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j-1] > s[j]; j-- {
+			s[j-1], s[j] = s[j], s[j-1]
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package qm
+
+import "testing"
+
+// evalCover reports whether m is covered by any cube in cover.
+func evalCover(cover Cover, m Minterm, vars int) bool {
+	for _, c := range cover {
+		if c.Covers(m, vars) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestMinimizeMatchesOnSet checks that the minimized cover accepts exactly
+// the requested on-set and nothing outside of it, mirroring
+// canAccessDocument from examples/agent/go/document_access.go:
+// isOwner || isAdmin || (isPublic && isEditor).
+func TestMinimizeMatchesOnSet(t *testing.T) {
+	const vars = 4 // isOwner, isAdmin, isEditor, isPublic
+	var on []Minterm
+	for m := 0; m < 1<<vars; m++ {
+		isOwner := m>>3&1 == 1
+		isAdmin := m>>2&1 == 1
+		isEditor := m>>1&1 == 1
+		isPublic := m&1 == 1
+		if isOwner || isAdmin || (isPublic && isEditor) {
+			on = append(on, Minterm(m))
+		}
+	}
+
+	cover := Minimize(vars, on, nil)
+
+	onSet := map[Minterm]bool{}
+	for _, m := range on {
+		onSet[m] = true
+	}
+	for m := 0; m < 1<<vars; m++ {
+		want := onSet[Minterm(m)]
+		got := evalCover(cover, Minterm(m), vars)
+		if got != want {
+			t.Errorf("minterm %04b: cover says %v, want %v", m, got, want)
+		}
+	}
+	if len(cover) > 3 {
+		t.Errorf("expected at most 3 product terms (isOwner, isAdmin, isEditor&&isPublic are all essential), got %d: %v", len(cover), cover)
+	}
+}
+
+func TestCombineRequiresSingleDifference(t *testing.T) {
+	a := Cube{One, Zero, DontCare}
+	b := Cube{One, One, DontCare}
+	merged, ok := combine(a, b)
+	if !ok {
+		t.Fatalf("expected a and b to combine")
+	}
+	want := Cube{One, DontCare, DontCare}
+	for i := range want {
+		if merged[i] != want[i] {
+			t.Errorf("merged[%d] = %v, want %v", i, merged[i], want[i])
+		}
+	}
+
+	c := Cube{Zero, One, DontCare}
+	if _, ok := combine(a, c); ok {
+		t.Errorf("a and c differ in two literals, should not combine")
+	}
+}
+
+// TestMinimizeDontCareShrinksCover mirrors the canAccessAdvancedAnalytics
+// shape from examples/agent/go/saas_feature_flags.go: a leading
+// `if isTrial && isAdmin { return false }` guard means the rest of the
+// function never has to justify isTrial && isAdmin as an explicit false —
+// it's unreachable by the time the remaining classifier runs. Marking
+// those minterms dc instead of off should let the cover drop a literal.
+func TestMinimizeDontCareShrinksCover(t *testing.T) {
+	const vars = 3                         // bits: isEnterprise, isAdmin, isTrial
+	reachableTrue := Minterm(0b110)        // isEnterprise && isAdmin && !isTrial
+	guardedByEarlyReturn := Minterm(0b111) // isEnterprise && isAdmin && isTrial -> already false
+
+	withoutDC := Minimize(vars, []Minterm{reachableTrue}, nil)
+	withDC := Minimize(vars, []Minterm{reachableTrue}, []Minterm{guardedByEarlyReturn})
+
+	if got := literalCount(withoutDC); got != 3 {
+		t.Fatalf("without dc: expected the single reachable minterm to need all 3 literals, got %d (%v)", got, withoutDC)
+	}
+	if got := literalCount(withDC); got != 2 {
+		t.Fatalf("with dc: expected the guard's minterm to absorb a literal, got %d (%v)", got, withDC)
+	}
+}
+
+func literalCount(cover Cover) int {
+	n := 0
+	for _, cube := range cover {
+		for _, t := range cube {
+			if t != DontCare {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,463 @@
+package qmlint
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/HJLebbink/quine-mccluskey-petrick-agent/qm"
+)
+
+// branch is one `if cond { <consequent> }` rung of a ladder.
+type branch struct {
+	ifStmt *ast.IfStmt
+	cond   ast.Expr
+	value  ast.Expr // the returned/assigned expression
+}
+
+// ladder is a maximal run of same-shaped if-branches, optionally followed by
+// a trailing statement that supplies the default outcome.
+type ladder struct {
+	branches   []branch
+	assignTo   *ast.Ident // non-nil for the assignment-ladder variant
+	trailing   *branch    // default outcome, if the ladder falls through to one
+	stmts      []ast.Stmt // every statement consumed, in order (for the fix range)
+	analyzable bool
+	vars       []string                    // discovered atomic predicates, first-seen order
+	tags       map[string]qm.PredicateTags // predicate name -> inferred tags
+	guardedBy  map[string][]guardReq       // predicate name -> prerequisites and the polarity each must hold
+}
+
+// collectLadder consumes a maximal run of if-branches (and an optional
+// trailing default) from the front of stmts, returning it along with
+// whatever statements remain unconsumed. It returns a nil ladder if stmts
+// doesn't start with a recognizable branch.
+func collectLadder(stmts []ast.Stmt) (*ladder, []ast.Stmt) {
+	if len(stmts) == 0 {
+		return nil, stmts
+	}
+	first, ok := classifyBranch(stmts[0])
+	if !ok {
+		return nil, stmts
+	}
+
+	l := &ladder{
+		assignTo:  first.assignTo,
+		tags:      map[string]qm.PredicateTags{},
+		guardedBy: map[string][]guardReq{},
+	}
+	seenVars := map[string]bool{}
+	addVars := func(names []string) {
+		for _, n := range names {
+			if !seenVars[n] {
+				seenVars[n] = true
+				l.vars = append(l.vars, n)
+			}
+		}
+	}
+
+	l.analyzable = true
+	i := 0
+	for i < len(stmts) {
+		b, ok := classifyBranch(stmts[i])
+		if !ok || !sameShape(first, b) {
+			break
+		}
+		names, analyzable := collectGuardAtoms(b.branch.cond, l.tags, l.guardedBy)
+		if !analyzable {
+			l.analyzable = false
+		} else {
+			addVars(names)
+		}
+		l.branches = append(l.branches, b.branch)
+		l.stmts = append(l.stmts, stmts[i])
+		i++
+	}
+	if len(l.branches) == 0 {
+		return nil, stmts
+	}
+
+	// An optional trailing statement supplies the default outcome: a bare
+	// return (return-style) or an assignment to the same variable
+	// (assignment-style) with no guard at all.
+	if i < len(stmts) {
+		if def, ok := classifyDefault(stmts[i], first); ok {
+			l.trailing = &def
+			l.stmts = append(l.stmts, stmts[i])
+			i++
+		}
+	}
+	return l, stmts[i:]
+}
+
+type classifiedBranch struct {
+	branch   branch
+	assignTo *ast.Ident // nil for return-style
+}
+
+// classifyBranch recognizes `if cond { return v }` and
+// `if cond { x = v }` (single statement body, no else).
+func classifyBranch(s ast.Stmt) (classifiedBranch, bool) {
+	ifStmt, ok := s.(*ast.IfStmt)
+	if !ok || ifStmt.Init != nil || ifStmt.Else != nil {
+		return classifiedBranch{}, false
+	}
+	if len(ifStmt.Body.List) != 1 {
+		return classifiedBranch{}, false
+	}
+	switch body := ifStmt.Body.List[0].(type) {
+	case *ast.ReturnStmt:
+		if len(body.Results) != 1 {
+			return classifiedBranch{}, false
+		}
+		return classifiedBranch{branch: branch{ifStmt: ifStmt, cond: ifStmt.Cond, value: body.Results[0]}}, true
+	case *ast.AssignStmt:
+		if body.Tok != token.ASSIGN || len(body.Lhs) != 1 || len(body.Rhs) != 1 {
+			return classifiedBranch{}, false
+		}
+		id, ok := body.Lhs[0].(*ast.Ident)
+		if !ok {
+			return classifiedBranch{}, false
+		}
+		return classifiedBranch{
+			branch:   branch{ifStmt: ifStmt, cond: ifStmt.Cond, value: body.Rhs[0]},
+			assignTo: id,
+		}, true
+	default:
+		return classifiedBranch{}, false
+	}
+}
+
+func sameShape(a, b classifiedBranch) bool {
+	if (a.assignTo == nil) != (b.assignTo == nil) {
+		return false
+	}
+	if a.assignTo != nil && a.assignTo.Name != b.assignTo.Name {
+		return false
+	}
+	return true
+}
+
+// classifyDefault recognizes the trailing statement that supplies the
+// ladder's default outcome: a bare `return v` for return-style ladders, or
+// `x = v` for assignment-style ladders targeting the same variable.
+func classifyDefault(s ast.Stmt, first classifiedBranch) (branch, bool) {
+	if first.assignTo == nil {
+		ret, ok := s.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return branch{}, false
+		}
+		return branch{value: ret.Results[0]}, true
+	}
+	assign, ok := s.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return branch{}, false
+	}
+	id, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || id.Name != first.assignTo.Name {
+		return branch{}, false
+	}
+	return branch{value: assign.Rhs[0]}, true
+}
+
+// evalGuard evaluates an already-analyzable guard against an assignment of
+// its variables. Composite structure mirrors collectGuardAtoms: only a
+// leading ! and &&/|| are decomposed, everything else (including a
+// channel-receive unary expression) is looked up as an atom by its printed
+// source text.
+func evalGuard(expr ast.Expr, env map[string]bool) bool {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return evalGuard(e.X, env)
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return !evalGuard(e.X, env)
+		}
+		return env[exprString(e)]
+	case *ast.BinaryExpr:
+		if e.Op == token.LAND {
+			return evalGuard(e.X, env) && evalGuard(e.Y, env)
+		}
+		return evalGuard(e.X, env) || evalGuard(e.Y, env)
+	default:
+		return env[exprString(e)]
+	}
+}
+
+func exprString(e ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, token.NewFileSet(), e)
+	return buf.String()
+}
+
+// reportLadder emits a diagnostic for l, with a SuggestedFix when every
+// guard is analyzable and the truth table is small enough to minimize.
+func reportLadder(pass *analysis.Pass, l *ladder) {
+	start := l.stmts[0].Pos()
+	end := l.stmts[len(l.stmts)-1].End()
+
+	if !l.analyzable {
+		pass.Report(analysis.Diagnostic{
+			Pos:     start,
+			End:     end,
+			Message: fmt.Sprintf("if-ladder with %d branches shares boolean inputs but a guard contains an expression (such as a comparison) qmlint doesn't decompose; not analyzable, skipping minimization", len(l.branches)),
+		})
+		return
+	}
+	if l.trailing == nil {
+		pass.Report(analysis.Diagnostic{
+			Pos:     start,
+			End:     end,
+			Message: fmt.Sprintf("if-ladder with %d branches shares boolean inputs but has no trailing default, so its truth table is incomplete; skipping minimization", len(l.branches)),
+		})
+		return
+	}
+	const maxVars = 20
+	if len(l.vars) > maxVars {
+		pass.Report(analysis.Diagnostic{
+			Pos:     start,
+			End:     end,
+			Message: fmt.Sprintf("if-ladder depends on %d boolean inputs, too many to enumerate exactly; skipping minimization", len(l.vars)),
+		})
+		return
+	}
+
+	var guards, rest []branch
+	if l.assignTo == nil {
+		guards, rest = splitLeadingGuards(l)
+	} else {
+		// An assignment-style branch never exits early the way a return
+		// does, so an earlier branch matching the ladder's default
+		// doesn't make later branches unreachable for those inputs —
+		// there's nothing here to carve out as don't-care.
+		rest = l.branches
+	}
+	tables, order, _, dc := buildTruthTables(l, guards, rest)
+
+	model, guardedBy := buildPredicateModel(l)
+	covers := make(map[string]qm.Cover, len(order))
+	for _, key := range order {
+		cover := qm.Minimize(len(l.vars), tables[key], dc)
+		for _, cube := range cover {
+			if model.Skip(cube, guardedBy) {
+				pass.Report(analysis.Diagnostic{
+					Pos:     start,
+					End:     end,
+					Message: fmt.Sprintf("if-ladder with %d branches could be minimized further, but doing so would evaluate a predicate that can panic somewhere the original code would have short-circuited past it first; skipping that simplification", len(l.branches)),
+				})
+				return
+			}
+		}
+		covers[key] = cover
+	}
+
+	if name, n := firstOverEvaluated(l, guards, order, covers); name != "" {
+		pass.Report(analysis.Diagnostic{
+			Pos:     start,
+			End:     end,
+			Message: fmt.Sprintf("if-ladder with %d branches could be minimized, but %s isn't free of side effects and the rewrite would test it in %d separate rendered if-conditions, which can call or evaluate it more often than the original; skipping that simplification", len(l.branches), name, n),
+		})
+		return
+	}
+
+	newText := renderMinimized(l, guards, order, covers, model)
+
+	pass.Report(analysis.Diagnostic{
+		Pos:     start,
+		End:     end,
+		Message: fmt.Sprintf("if-ladder with %d branches over %d shared boolean inputs can be minimized with Quine-McCluskey", len(l.branches), len(l.vars)),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "rewrite as a minimized if-ladder",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     start,
+				End:     end,
+				NewText: []byte(newText),
+			}},
+		}},
+	})
+}
+
+// splitLeadingGuards peels off the maximal prefix of l.branches whose
+// result matches the ladder's own trailing default. Such a guard (e.g.
+// `if isTrial && isAdmin { return false }` ahead of a run of `return true`
+// branches that otherwise default to false) doesn't classify anything on
+// its own — it just carves minterms out of the space the rest of the
+// ladder has to describe, so it's kept verbatim ahead of the minimized
+// remainder instead of being folded into the truth table.
+//
+// If every branch matches the default (nothing left to minimize), this
+// returns no guards so the whole ladder still gets minimized as before.
+func splitLeadingGuards(l *ladder) (guards, rest []branch) {
+	defaultKey := exprString(l.trailing.value)
+	i := 0
+	for i < len(l.branches) && exprString(l.branches[i].value) == defaultKey {
+		i++
+	}
+	if i == len(l.branches) {
+		return nil, l.branches
+	}
+	return l.branches[:i], l.branches[i:]
+}
+
+// buildTruthTables evaluates every combination of l.vars against rest and
+// groups the resulting minterms by outcome, using the priority order that
+// matches how rest actually executes: for a return-style ladder (no
+// assignTo) the first matching guard wins, since that branch returns
+// immediately and nothing after it ever runs; for an assignment-style
+// ladder every `if` executes regardless of the others, so the LAST
+// matching guard's assignment is the one that survives. Minterms that a
+// leading guard already disposes of are reported as dc instead of being
+// classified, since the rewritten code never reaches rest for them: the
+// minimizer is free to claim them for any outcome without being judged on
+// it — callers only pass leading guards for the return-style ladders that
+// actually exit early on them. order lists the non-default outcome keys
+// in first-seen order; defaultKey is the outcome the trailing statement
+// supplies.
+func buildTruthTables(l *ladder, guards, rest []branch) (tables map[string][]qm.Minterm, order []string, defaultKey string, dc []qm.Minterm) {
+	tables = map[string][]qm.Minterm{}
+	keyOf := map[*branch]string{}
+	for i := range rest {
+		b := &rest[i]
+		keyOf[b] = exprString(b.value)
+	}
+	defaultKey = exprString(l.trailing.value)
+
+	vars := l.vars
+	n := len(vars)
+	total := 1 << uint(n)
+	for m := 0; m < total; m++ {
+		env := make(map[string]bool, n)
+		for i, v := range vars {
+			env[v] = (m>>uint(n-1-i))&1 == 1
+		}
+		if guardHits(guards, env) {
+			dc = append(dc, qm.Minterm(m))
+			continue
+		}
+		outcome := defaultKey
+		for i := range rest {
+			if evalGuard(rest[i].cond, env) {
+				outcome = keyOf[&rest[i]]
+				if l.assignTo == nil {
+					break
+				}
+			}
+		}
+		if outcome == defaultKey {
+			continue
+		}
+		if _, seen := tables[outcome]; !seen {
+			order = append(order, outcome)
+		}
+		tables[outcome] = append(tables[outcome], qm.Minterm(m))
+	}
+	return tables, order, defaultKey, dc
+}
+
+// guardHits reports whether any guard's condition is satisfied by env.
+func guardHits(guards []branch, env map[string]bool) bool {
+	for _, g := range guards {
+		if evalGuard(g.cond, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstOverEvaluated reports the first (in l.vars order) non-Pure
+// predicate that the rendered guards and covers would test in more than
+// one if-condition, along with how many. Rendering the same cube's
+// literal in two different ifs means two separate runtime evaluations of
+// that literal's predicate, which is only safe for Pure predicates — a
+// Costly, SideEffecting, or MayPanic one gets called as many times as the
+// source says it should, not once per rendered branch that happens to
+// share it.
+func firstOverEvaluated(l *ladder, guards []branch, order []string, covers map[string]qm.Cover) (name string, count int) {
+	counts := map[string]int{}
+	for _, g := range guards {
+		atoms, _ := collectGuardAtoms(g.cond, map[string]qm.PredicateTags{}, map[string][]guardReq{})
+		for _, a := range atoms {
+			counts[a]++
+		}
+	}
+	for _, key := range order {
+		for _, cube := range covers[key] {
+			for i, t := range cube {
+				if t != qm.DontCare {
+					counts[l.vars[i]]++
+				}
+			}
+		}
+	}
+	for _, name := range l.vars {
+		if !l.tags[name].Pure() && counts[name] > 1 {
+			return name, counts[name]
+		}
+	}
+	return "", 0
+}
+
+// renderMinimized prints the minimized ladder: any leading guards verbatim
+// (unchanged, since they're what let the rest of the minterms be treated
+// as don't-cares), then one `if <cover> { <result> }` per non-default
+// outcome in first-seen order, then the original trailing default
+// statement.
+func renderMinimized(l *ladder, guards []branch, order []string, covers map[string]qm.Cover, model qm.PredicateModel) string {
+	var buf bytes.Buffer
+	for _, g := range guards {
+		fmt.Fprintf(&buf, "if %s {\n\t%s\n}\n", exprString(g.cond), resultStmt(l, exprString(g.value)))
+	}
+	for _, key := range order {
+		for _, cube := range covers[key] {
+			cond := cubeString(cube, l.vars, model)
+			fmt.Fprintf(&buf, "if %s {\n\t%s\n}\n", cond, resultStmt(l, key))
+		}
+	}
+	buf.WriteString(trailingStmt(l))
+	return buf.String()
+}
+
+func resultStmt(l *ladder, valueSrc string) string {
+	if l.assignTo == nil {
+		return "return " + valueSrc
+	}
+	return l.assignTo.Name + " = " + valueSrc
+}
+
+func trailingStmt(l *ladder) string {
+	return resultStmt(l, exprString(l.trailing.value))
+}
+
+// cubeString renders cube's fixed literals as a single &&-chain, ordered
+// by model.OrderLiterals so side-effecting and panic-prone predicates keep
+// their original relative order and costly predicates land behind cheap
+// ones rather than in raw variable-index order.
+func cubeString(cube qm.Cube, vars []string, model qm.PredicateModel) string {
+	var indices []int
+	for i, t := range cube {
+		if t != qm.DontCare {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == 0 {
+		return "true"
+	}
+	indices = model.OrderLiterals(indices)
+
+	out := literalString(cube, vars, indices[0])
+	for _, i := range indices[1:] {
+		out += " && " + literalString(cube, vars, i)
+	}
+	return out
+}
+
+func literalString(cube qm.Cube, vars []string, i int) string {
+	if cube[i] == qm.Zero {
+		return "!" + vars[i]
+	}
+	return vars[i]
+}
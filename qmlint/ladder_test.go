@@ -0,0 +1,210 @@
+package qmlint
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/HJLebbink/quine-mccluskey-petrick-agent/qm"
+)
+
+func parseLadder(t *testing.T, src string, skip int) *ladder {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	var body *ast.BlockStmt
+	ast.Inspect(f, func(n ast.Node) bool {
+		if fn, ok := n.(*ast.FuncDecl); ok {
+			body = fn.Body
+		}
+		return true
+	})
+	lad, _ := collectLadder(body.List[skip:])
+	if lad == nil {
+		t.Fatal("expected collectLadder to recognize a ladder")
+	}
+	return lad
+}
+
+func TestFirstOverEvaluatedCatchesDuplicateCalls(t *testing.T) {
+	l := &ladder{
+		vars: []string{"isAdmin", "db.Lookup(id)"},
+		tags: map[string]qm.PredicateTags{
+			"db.Lookup(id)": {Costly: true, SideEffecting: true, MayPanic: true},
+		},
+	}
+	order := []string{`"a"`, `"b"`}
+	covers := map[string]qm.Cover{
+		`"a"`: {qm.Cube{qm.One, qm.One}},
+		`"b"`: {qm.Cube{qm.Zero, qm.One}, qm.Cube{qm.One, qm.Zero}},
+	}
+
+	name, count := firstOverEvaluated(l, nil, order, covers)
+	if name != "db.Lookup(id)" || count < 2 {
+		t.Fatalf("expected db.Lookup(id) flagged as over-evaluated, got name=%q count=%d", name, count)
+	}
+}
+
+func TestFirstOverEvaluatedAllowsPureRepeats(t *testing.T) {
+	l := &ladder{
+		vars: []string{"isAdmin", "isOwner"},
+		tags: map[string]qm.PredicateTags{},
+	}
+	order := []string{`"a"`, `"b"`}
+	covers := map[string]qm.Cover{
+		`"a"`: {qm.Cube{qm.One, qm.DontCare}},
+		`"b"`: {qm.Cube{qm.Zero, qm.One}, qm.Cube{qm.One, qm.Zero}},
+	}
+
+	if name, _ := firstOverEvaluated(l, nil, order, covers); name != "" {
+		t.Fatalf("expected pure predicates to be allowed to repeat across rendered branches, got %q flagged", name)
+	}
+}
+
+// TestBuildTruthTablesAssignmentStyleLastMatchWins guards against
+// buildTruthTables treating an assignment-style ladder (a sequence of
+// independent `if`s, each of which can overwrite the result) like an
+// if/else-if chain that exits on the first match. At runtime every `if`
+// here executes, so whichever matching branch comes last wins.
+func TestBuildTruthTablesAssignmentStyleLastMatchWins(t *testing.T) {
+	lad := parseLadder(t, `package p
+func classify(a, b, c bool) string {
+	if a {
+		x = "first"
+	}
+	if b {
+		x = "second"
+	}
+	if c {
+		x = "third"
+	}
+	x = "none"
+	return x
+}
+`, 0)
+	if lad.assignTo == nil {
+		t.Fatal("expected an assignment-style ladder")
+	}
+
+	tables, _, defaultKey, _ := buildTruthTables(lad, nil, lad.branches)
+
+	allTrue := qm.Minterm(1<<uint(len(lad.vars)) - 1)
+	got := defaultKey
+	for outcome, minterms := range tables {
+		for _, m := range minterms {
+			if m == allTrue {
+				got = outcome
+			}
+		}
+	}
+	if got != `"third"` {
+		t.Errorf("a=b=c=true: expected the last matching branch (%q) to win, got %s", `"third"`, got)
+	}
+}
+
+// TestBuildTruthTablesReturnStyleFirstMatchWins is the return-style
+// counterpart: a branch that returns exits immediately, so the first
+// matching guard must still win even though later guards also match.
+func TestBuildTruthTablesReturnStyleFirstMatchWins(t *testing.T) {
+	lad := parseLadder(t, `package p
+func classify(a, b, c bool) string {
+	if a {
+		return "first"
+	}
+	if b {
+		return "second"
+	}
+	if c {
+		return "third"
+	}
+	return "none"
+}
+`, 0)
+	if lad.assignTo != nil {
+		t.Fatal("expected a return-style ladder")
+	}
+
+	tables, _, defaultKey, _ := buildTruthTables(lad, nil, lad.branches)
+
+	allTrue := qm.Minterm(1<<uint(len(lad.vars)) - 1)
+	got := defaultKey
+	for outcome, minterms := range tables {
+		for _, m := range minterms {
+			if m == allTrue {
+				got = outcome
+			}
+		}
+	}
+	if got != `"first"` {
+		t.Errorf("a=b=c=true: expected the first matching branch (%q) to win, got %s", `"first"`, got)
+	}
+}
+
+// TestReportLadderAssignmentStyleFixPreservesSemantics is an end-to-end
+// companion to TestBuildTruthTablesAssignmentStyleLastMatchWins: it drives
+// reportLadder itself and checks that the SuggestedFix it emits, when
+// rendered back out as independent ifs and simulated the same way the
+// runtime would execute them (every if runs; the last match wins), produces
+// the same outcome as the original ladder for every input. A minimizer that
+// silently changed which branch wins would fail this even though it never
+// touches a MayPanic predicate, which is what the safety checks in
+// reportLadder otherwise gate on.
+func TestReportLadderAssignmentStyleFixPreservesSemantics(t *testing.T) {
+	lad := parseLadder(t, `package p
+func classify(a, b, c bool) string {
+	if a {
+		x = "first"
+	}
+	if b {
+		x = "second"
+	}
+	if c {
+		x = "third"
+	}
+	x = "none"
+	return x
+}
+`, 0)
+
+	var fix string
+	pass := &analysis.Pass{Report: func(d analysis.Diagnostic) {
+		if len(d.SuggestedFixes) == 1 {
+			fix = string(d.SuggestedFixes[0].TextEdits[0].NewText)
+		}
+	}}
+	reportLadder(pass, lad)
+	if fix == "" {
+		t.Fatal("expected reportLadder to emit a SuggestedFix")
+	}
+
+	rendered := parseLadder(t, "package p\nfunc classify(a, b, c bool) string {\n"+fix+"\n}\n", 0)
+
+	for m := qm.Minterm(0); m < 1<<3; m++ {
+		env := map[string]bool{"a": m&4 != 0, "b": m&2 != 0, "c": m&1 != 0}
+		want := simulateAssignment(lad, env)
+		got := simulateAssignment(rendered, env)
+		if got != want {
+			t.Errorf("a=%v b=%v c=%v: original yields %s, minimized fix yields %s", env["a"], env["b"], env["c"], want, got)
+		}
+	}
+}
+
+// simulateAssignment replays an assignment-style ladder's branches in
+// source order against env exactly as the runtime would: every branch
+// whose guard holds assigns, with no early exit, so whichever matching
+// branch appears last determines the final outcome.
+func simulateAssignment(l *ladder, env map[string]bool) string {
+	outcome := exprString(l.trailing.value)
+	for i := range l.branches {
+		if evalGuard(l.branches[i].cond, env) {
+			outcome = exprString(l.branches[i].value)
+		}
+	}
+	return outcome
+}
@@ -0,0 +1,257 @@
+package qmlint
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/HJLebbink/quine-mccluskey-petrick-agent/qm"
+)
+
+// guardReq records that, within some guard expression, name had to
+// evaluate to require for evaluation to proceed to the atom it precedes.
+// Any means the exact polarity couldn't be pinned down (see
+// trueRequirement/falseRequirement) and only "was fixed to some value at
+// all" can be demanded.
+type guardReq struct {
+	name    string
+	require qm.Trit
+	any     bool
+}
+
+// collectGuardAtoms walks a boolean guard expression, decomposing &&, ||,
+// !, and parens, and records each atomic condition it bottoms out at: a
+// bare identifier or field select, but also (unlike a plain boolean-only
+// reading of the guard) a call, index expression, type assertion, or
+// channel receive — those are real predicates too, just ones the rest of
+// qmlint has to handle with care. tags accumulates each atom's inferred
+// PredicateTags (merged by name, since the same atom can recur across
+// branches); guardedBy accumulates, for each atom, the prerequisites that
+// had to hold — and the polarity they had to hold at, where that's
+// precisely knowable — within the same guard expression, short-circuit
+// evaluation implies.
+//
+// Any other shape — a non-&&/|| *ast.BinaryExpr (a comparison, say), or an
+// expression such as a pointer dereference that isn't one of the atom
+// shapes above — is out of scope and makes the whole guard not analyzable.
+func collectGuardAtoms(expr ast.Expr, tags map[string]qm.PredicateTags, guardedBy map[string][]guardReq) (atoms []string, ok bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return collectGuardAtoms(e.X, tags, guardedBy)
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return collectGuardAtoms(e.X, tags, guardedBy)
+		}
+		return addAtom(e, tags), true
+	case *ast.BinaryExpr:
+		if e.Op != token.LAND && e.Op != token.LOR {
+			return nil, false
+		}
+		left, ok1 := collectGuardAtoms(e.X, tags, guardedBy)
+		right, ok2 := collectGuardAtoms(e.Y, tags, guardedBy)
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		// The left operand must evaluate true (&&) or false (||) before
+		// the right operand is ever reached. trueRequirement/
+		// falseRequirement try to pin an exact per-atom value down
+		// through any NOTs and nested &&/||; when the shape is too
+		// irregular for that (e.g. an && required false by an outer ||),
+		// fall back to demanding every left atom be fixed to *some* value,
+		// which is still enough to keep Skip safe, just more conservative.
+		var reqs map[string]qm.Trit
+		var precise bool
+		if e.Op == token.LAND {
+			reqs, precise = trueRequirement(e.X)
+		} else {
+			reqs, precise = falseRequirement(e.X)
+		}
+		for _, r := range right {
+			if precise {
+				guardedBy[r] = appendExactRequirement(guardedBy[r], reqs)
+			} else {
+				guardedBy[r] = appendAnyRequirement(guardedBy[r], left)
+			}
+		}
+		return append(left, right...), true
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.TypeAssertExpr:
+		return addAtom(expr, tags), true
+	default:
+		return nil, false
+	}
+}
+
+func addAtom(e ast.Expr, tags map[string]qm.PredicateTags) []string {
+	name := exprString(e)
+	t := tags[name]
+	merge := inferTags(e)
+	t.Costly = t.Costly || merge.Costly
+	t.SideEffecting = t.SideEffecting || merge.SideEffecting
+	t.MayPanic = t.MayPanic || merge.MayPanic
+	tags[name] = t
+	return []string{name}
+}
+
+// trueRequirement returns the value each atom in expr must hold for expr
+// itself to evaluate true, provided expr is built only from atoms and &&
+// (so truth really does pin down every atom at once), where a NOT hands
+// its operand to falseRequirement; ok is false for anything else,
+// including an || or a negated &&, since "a || b is true" and
+// "!(a && b) is true" don't fix either atom to a single value.
+func trueRequirement(expr ast.Expr) (reqs map[string]qm.Trit, ok bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return trueRequirement(e.X)
+	case *ast.UnaryExpr:
+		if e.Op != token.NOT {
+			return map[string]qm.Trit{exprString(e): qm.One}, true
+		}
+		return falseRequirement(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.LAND {
+			return nil, false
+		}
+		return mergeRequirements(e.X, e.Y, trueRequirement)
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.TypeAssertExpr:
+		return map[string]qm.Trit{exprString(e): qm.One}, true
+	default:
+		return nil, false
+	}
+}
+
+// falseRequirement is trueRequirement's mirror image: it pins down every
+// atom in expr only when expr evaluating false fixes them all at once,
+// which holds for atoms, NOT, and || (De Morgan's law turns "a || b is
+// false" into the conjunction "a is false and b is false"), but not &&.
+func falseRequirement(expr ast.Expr) (reqs map[string]qm.Trit, ok bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return falseRequirement(e.X)
+	case *ast.UnaryExpr:
+		if e.Op != token.NOT {
+			return map[string]qm.Trit{exprString(e): qm.Zero}, true
+		}
+		return trueRequirement(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.LOR {
+			return nil, false
+		}
+		return mergeRequirements(e.X, e.Y, falseRequirement)
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.TypeAssertExpr:
+		return map[string]qm.Trit{exprString(e): qm.Zero}, true
+	default:
+		return nil, false
+	}
+}
+
+func mergeRequirements(x, y ast.Expr, of func(ast.Expr) (map[string]qm.Trit, bool)) (map[string]qm.Trit, bool) {
+	left, ok1 := of(x)
+	right, ok2 := of(y)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	merged := make(map[string]qm.Trit, len(left)+len(right))
+	for name, want := range left {
+		merged[name] = want
+	}
+	for name, want := range right {
+		if existing, dup := merged[name]; dup && existing != want {
+			return nil, false // contradictory requirement for the same atom
+		}
+		merged[name] = want
+	}
+	return merged, true
+}
+
+func flipAll(reqs map[string]qm.Trit) map[string]qm.Trit {
+	flipped := make(map[string]qm.Trit, len(reqs))
+	for name, want := range reqs {
+		if want == qm.One {
+			flipped[name] = qm.Zero
+		} else {
+			flipped[name] = qm.One
+		}
+	}
+	return flipped
+}
+
+func appendExactRequirement(dst []guardReq, reqs map[string]qm.Trit) []guardReq {
+	have := map[guardReq]bool{}
+	for _, d := range dst {
+		have[d] = true
+	}
+	for name, want := range reqs {
+		req := guardReq{name: name, require: want}
+		if !have[req] {
+			have[req] = true
+			dst = append(dst, req)
+		}
+	}
+	return dst
+}
+
+func appendAnyRequirement(dst []guardReq, names []string) []guardReq {
+	have := map[guardReq]bool{}
+	for _, d := range dst {
+		have[d] = true
+	}
+	for _, n := range names {
+		req := guardReq{name: n, any: true}
+		if !have[req] {
+			have[req] = true
+			dst = append(dst, req)
+		}
+	}
+	return dst
+}
+
+// inferTags inspects e's own AST shape (including anything nested inside
+// it, e.g. a field select off a call result) for the constructs that make
+// a predicate worth gating or unsafe to reorder: a call is assumed Costly,
+// SideEffecting, and MayPanic (the AST alone can't prove otherwise); an
+// index expression or type assertion is assumed MayPanic; a channel
+// receive is assumed SideEffecting and MayPanic. A bare identifier or
+// field-select chain infers no tags at all (Pure).
+func inferTags(e ast.Expr) qm.PredicateTags {
+	var tags qm.PredicateTags
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.CallExpr:
+			tags.Costly, tags.SideEffecting, tags.MayPanic = true, true, true
+		case *ast.IndexExpr:
+			tags.MayPanic = true
+		case *ast.TypeAssertExpr:
+			tags.MayPanic = true
+		case *ast.UnaryExpr:
+			if v.Op == token.ARROW {
+				tags.SideEffecting, tags.MayPanic = true, true
+			}
+		}
+		return true
+	})
+	return tags
+}
+
+// buildPredicateModel turns a ladder's accumulated per-atom tags and
+// guardedBy edges into a qm.PredicateModel plus a guardedBy map indexed by
+// l.vars position, ready for qm.PredicateModel.Skip and OrderLiterals.
+func buildPredicateModel(l *ladder) (qm.PredicateModel, map[int][]qm.Guard) {
+	indexOf := make(map[string]int, len(l.vars))
+	preds := make([]qm.Predicate, len(l.vars))
+	for i, name := range l.vars {
+		indexOf[name] = i
+		preds[i] = qm.Predicate{Name: name, Tags: l.tags[name]}
+	}
+	guardedBy := make(map[int][]qm.Guard, len(l.vars))
+	for name, reqs := range l.guardedBy {
+		i, ok := indexOf[name]
+		if !ok {
+			continue
+		}
+		for _, r := range reqs {
+			if j, ok := indexOf[r.name]; ok {
+				guardedBy[i] = append(guardedBy[i], qm.Guard{Pos: j, Require: r.require, Any: r.any})
+			}
+		}
+	}
+	return qm.NewPredicateModel(preds), guardedBy
+}
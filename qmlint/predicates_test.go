@@ -0,0 +1,93 @@
+package qmlint
+
+import (
+	"go/parser"
+	"testing"
+
+	"github.com/HJLebbink/quine-mccluskey-petrick-agent/qm"
+)
+
+func parseGuard(t *testing.T, src string) (atoms []string, tags map[string]qm.PredicateTags, guardedBy map[string][]guardReq) {
+	t.Helper()
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		t.Fatalf("ParseExpr(%q): %v", src, err)
+	}
+	tags = map[string]qm.PredicateTags{}
+	guardedBy = map[string][]guardReq{}
+	atoms, ok := collectGuardAtoms(expr, tags, guardedBy)
+	if !ok {
+		t.Fatalf("collectGuardAtoms(%q) reported not analyzable", src)
+	}
+	return atoms, tags, guardedBy
+}
+
+func TestCollectGuardAtomsTagsCallsAsUnsafe(t *testing.T) {
+	atoms, tags, guardedBy := parseGuard(t, "user.IsAdmin() && db.Lookup(id)")
+	if len(atoms) != 2 {
+		t.Fatalf("expected 2 atoms, got %v", atoms)
+	}
+	if tags[atoms[0]].Pure() {
+		t.Errorf("user.IsAdmin() should not be classified Pure")
+	}
+	if !tags[atoms[1]].MayPanic || !tags[atoms[1]].Costly {
+		t.Errorf("db.Lookup(id) should be tagged Costly and MayPanic, got %+v", tags[atoms[1]])
+	}
+	want := guardReq{name: atoms[0], require: qm.One}
+	if got := guardedBy[atoms[1]]; len(got) != 1 || got[0] != want {
+		t.Errorf("db.Lookup(id) should be guarded by user.IsAdmin() == true (&&), got %v", got)
+	}
+}
+
+func TestCollectGuardAtomsThreadsOrPolarity(t *testing.T) {
+	atoms, _, guardedBy := parseGuard(t, "isAdmin || db.Lookup(id)")
+	want := guardReq{name: atoms[0], require: qm.Zero}
+	if got := guardedBy[atoms[1]]; len(got) != 1 || got[0] != want {
+		t.Errorf("db.Lookup(id) should be guarded by isAdmin == false (||), got %v", got)
+	}
+}
+
+func TestCollectGuardAtomsFlipsPolarityThroughNot(t *testing.T) {
+	atoms, _, guardedBy := parseGuard(t, "!isAdmin && db.Lookup(id)")
+	want := guardReq{name: atoms[0], require: qm.Zero}
+	if got := guardedBy[atoms[1]]; len(got) != 1 || got[0] != want {
+		t.Errorf("db.Lookup(id) should be guarded by isAdmin == false (!isAdmin && ...), got %v", got)
+	}
+}
+
+func TestCollectGuardAtomsFallsBackToAnyForMixedShapes(t *testing.T) {
+	// (a || b) && db.Lookup(id): reaching Lookup requires a||b true, which
+	// doesn't pin a single value for a or b individually, so the guard
+	// should fall back to "fixed to some value" rather than claim a wrong
+	// exact polarity.
+	atoms, _, guardedBy := parseGuard(t, "(a || b) && db.Lookup(id)")
+	lookup := atoms[len(atoms)-1]
+	got := guardedBy[lookup]
+	if len(got) != 2 {
+		t.Fatalf("expected 2 Any prerequisites for db.Lookup(id), got %v", got)
+	}
+	for _, g := range got {
+		if !g.any {
+			t.Errorf("expected %+v to fall back to Any, not claim an exact polarity", g)
+		}
+	}
+}
+
+func TestCollectGuardAtomsIdentsArePure(t *testing.T) {
+	atoms, tags, _ := parseGuard(t, "isOwner || isAdmin")
+	for _, a := range atoms {
+		if !tags[a].Pure() {
+			t.Errorf("%s: expected Pure, got %+v", a, tags[a])
+		}
+	}
+}
+
+func TestCollectGuardAtomsRejectsComparisons(t *testing.T) {
+	expr, err := parser.ParseExpr("count > 0")
+	if err != nil {
+		t.Fatalf("ParseExpr: %v", err)
+	}
+	if _, ok := collectGuardAtoms(expr, map[string]qm.PredicateTags{}, map[string][]guardReq{}); ok {
+		t.Errorf("expected a bare comparison to be reported as not analyzable")
+	}
+}
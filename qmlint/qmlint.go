@@ -0,0 +1,78 @@
+// Package qmlint is a go/analysis Analyzer that finds if-ladders built out
+// of boolean guards sharing the same inputs, lifts them into a truth table,
+// minimizes that table with package qm, and suggests rewriting the ladder
+// into the minimized form.
+//
+// It is meant to be wired into a golangci-lint plugin or run directly via
+// the standard analysis driver (multichecker/singlechecker), rather than
+// used as a stand-alone demo.
+package qmlint
+
+import (
+	"flag"
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report if-ladders over the same boolean inputs that Quine-McCluskey can shrink
+
+qmlint scans if <boolexpr> { return <const> } ladders (and the assignment
+variant, whose default comes from a trailing assignment to the same
+variable), collects the atomic
+conditions each guard refers to, and builds a truth table per distinct
+result. A leading guard whose result matches the ladder's own default is
+treated as carving don't-care minterms out of the rest of the table rather
+than being folded into it, since the code after it never runs for those
+inputs.
+
+Guards built from a call, channel receive, index expression, or type
+assertion are tagged via qm.PredicateModel instead of being rejected
+outright: the suggested fix keeps side-effecting predicates in their
+original relative order, never evaluates a panic-prone predicate somewhere
+the original code would have short-circuited past it, and gates costly
+predicates behind cheap ones where it's free to choose. A guard is
+reported not analyzable when it contains an expression qmlint doesn't
+decompose, such as a comparison or a pointer dereference, and a
+simplification is skipped (with a diagnostic
+explaining why) when it can't be made safely.`
+
+// Analyzer reports redundant and reorderable boolean if-ladders.
+var Analyzer = &analysis.Analyzer{
+	Name:     "qmlint",
+	Doc:      doc,
+	Flags:    newFlagSet(),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var minBranches int
+
+func newFlagSet() flag.FlagSet {
+	fs := flag.NewFlagSet("qmlint", flag.ExitOnError)
+	fs.IntVar(&minBranches, "min-branches", 3, "only report ladders with at least this many if-branches")
+	return *fs
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{(*ast.BlockStmt)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		block := n.(*ast.BlockStmt)
+		for i := 0; i < len(block.List); {
+			lad, rest := collectLadder(block.List[i:])
+			if lad == nil {
+				i++
+				continue
+			}
+			if len(lad.branches) >= minBranches {
+				reportLadder(pass, lad)
+			}
+			i += len(block.List[i:]) - len(rest)
+		}
+	})
+	return nil, nil
+}